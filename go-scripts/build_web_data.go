@@ -40,6 +40,14 @@ type WebTrack struct {
 	VolumeAdjustment  *int   `json:"volumeAdjustment"` // Nullable (if 0 -> None)
 	Equalizer         string `json:"equalizer,omitempty"`
 	Comments          string `json:"comments,omitempty"`
+	MBRecordingID       string   `json:"mbRecordingId,omitempty"`
+	MBReleaseID         string   `json:"mbReleaseId,omitempty"`
+	MBArtistID          string   `json:"mbArtistId,omitempty"`
+	CoverArtURL         string   `json:"coverArtUrl,omitempty"`
+	ReplayGainTrackGain *float64 `json:"replayGainTrackGain,omitempty"`
+	ReplayGainTrackPeak *float64 `json:"replayGainTrackPeak,omitempty"`
+	SortName            string   `json:"sortName"`
+	OrderName           string   `json:"orderName"`
 }
 
 type ChunkData struct {
@@ -52,6 +60,8 @@ type ChunkData struct {
 type ArtistIndexEntry struct {
 	Slug       string   `json:"slug"`
 	Name       string   `json:"name"`
+	SortName   string   `json:"sortName"`
+	OrderName  string   `json:"orderName"`
 	AlbumCount int      `json:"albumCount"`
 	TrackCount int      `json:"trackCount"`
 	Albums     []string `json:"albums"`
@@ -63,11 +73,16 @@ type ArtistIndex struct {
 }
 
 type AlbumIndexEntry struct {
-	Slug        string   `json:"slug"`
-	Name        string   `json:"name"`
-	ArtistCount int      `json:"artistCount"`
-	TrackCount  int      `json:"trackCount"`
-	Artists     []string `json:"artists"`
+	Slug                string   `json:"slug"`
+	Name                string   `json:"name"`
+	SortName            string   `json:"sortName"`
+	OrderName           string   `json:"orderName"`
+	ArtistCount         int      `json:"artistCount"`
+	TrackCount          int      `json:"trackCount"`
+	Artists             []string `json:"artists"`
+	CoverArtURL         string   `json:"coverArtUrl,omitempty"`
+	ReplayGainAlbumGain *float64 `json:"replayGainAlbumGain,omitempty"`
+	ReplayGainAlbumPeak *float64 `json:"replayGainAlbumPeak,omitempty"`
 }
 
 type AlbumIndex struct {
@@ -104,13 +119,13 @@ func runBuildWebData() {
 		os.Exit(1)
 	}
 
-	fmt.Printf("Reading CSV from: %s\n", csvPath)
+	source := sourceFlag(csvPath)
+	fmt.Printf("Reading from source: %T\n", source)
 	fmt.Printf("Output directory: %s\n\n", outputDir)
 
-
-rows, err := ReadCSV(csvPath)
+	rows, err := source.Rows()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading CSV: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error reading source: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -119,15 +134,20 @@ rows, err := ReadCSV(csvPath)
 	// Index maps
 	type ArtistData struct {
 		Name     string
+		SortName string
 		Albums   map[string]bool
 		TrackIDs []string
 	}
 	artistsMap := make(map[string]*ArtistData)
 
 	type AlbumData struct {
-		Name     string
-		Artists  map[string]bool
-		TrackIDs []string
+		Name        string
+		SortName    string
+		Artists     map[string]bool
+		TrackIDs    []string
+		CoverArtURL string
+		AlbumGain   *float64
+		AlbumPeak   *float64
 	}
 	albumsMap := make(map[string]*AlbumData)
 
@@ -184,6 +204,19 @@ comments := SafeStr(row["Comments"])
 volumeAdjustment := SafeInt(row["Volume Adjustment"])
 equalizer := SafeStr(row["Equalizer"])
 
+		trackSortName := SafeStr(row["Sort Name"])
+		if trackSortName == "" {
+			trackSortName = trackName
+		}
+		artistSortName := SafeStr(row["Sort Artist"])
+		if artistSortName == "" {
+			artistSortName = ToSortName(artistName)
+		}
+		albumSortName := SafeStr(row["Sort Album"])
+		if albumSortName == "" {
+			albumSortName = ToSortName(albumName)
+		}
+
 		trackID := fmt.Sprintf("track-%05d", len(tracks))
 		artistSlug := Slugify(artistName)
 		albumSlug := Slugify(albumName)
@@ -237,6 +270,8 @@ equalizer := SafeStr(row["Equalizer"])
 			VolumeAdjustment:  volPtr,
 			Equalizer:         equalizer,
 			Comments:          comments,
+			SortName:          trackSortName,
+			OrderName:         NaturalPad(ToOrderName(trackSortName)),
 		}
 		
 		tracks = append(tracks, track)
@@ -244,8 +279,9 @@ equalizer := SafeStr(row["Equalizer"])
 		// Update indices
 		if _, ok := artistsMap[artistSlug]; !ok {
 			artistsMap[artistSlug] = &ArtistData{
-				Name:   artistName,
-				Albums: make(map[string]bool),
+				Name:     artistName,
+				SortName: artistSortName,
+				Albums:   make(map[string]bool),
 			}
 		}
 		artistsMap[artistSlug].Albums[albumName] = true
@@ -253,8 +289,9 @@ equalizer := SafeStr(row["Equalizer"])
 
 		if _, ok := albumsMap[albumSlug]; !ok {
 			albumsMap[albumSlug] = &AlbumData{
-				Name:    albumName,
-				Artists: make(map[string]bool),
+				Name:     albumName,
+				SortName: albumSortName,
+				Artists:  make(map[string]bool),
 			}
 		}
 		albumsMap[albumSlug].Artists[artistName] = true
@@ -282,6 +319,99 @@ equalizer := SafeStr(row["Equalizer"])
 	fmt.Printf("Found %d genres\n", len(genresSet))
 	fmt.Printf("Found %d years\n\n", len(yearsSet))
 
+	if shouldEnrich() {
+		enricher := newMusicBrainzEnricher(filepath.Join(outputDir, "cache", "musicbrainz"), enrichBudget())
+		fmt.Printf("\nEnriching tracks via MusicBrainz (budget %d)...\n", enricher.budget)
+		enriched := 0
+		for i := range tracks {
+			if tracks[i].MBRecordingID != "" {
+				continue
+			}
+			result, err := enricher.Lookup(tracks[i].Artist, tracks[i].Album, tracks[i].Name, tracks[i].Duration)
+			if err != nil {
+				if err == errEnrichBudgetExhausted {
+					break
+				}
+				continue
+			}
+			tracks[i].MBRecordingID = result.RecordingID
+			tracks[i].MBReleaseID = result.ReleaseID
+			tracks[i].MBArtistID = result.ArtistID
+			tracks[i].CoverArtURL = result.CoverArtURL
+			if data, ok := albumsMap[tracks[i].AlbumSlug]; ok && data.CoverArtURL == "" {
+				data.CoverArtURL = result.CoverArtURL
+			}
+			enriched++
+		}
+		fmt.Printf("Enriched %d/%d tracks\n\n", enriched, len(tracks))
+	}
+
+	loudnessMode := loudnessModeFlag()
+	var loudnessEntries []LoudnessEntry
+	var albumLoudness []AlbumLoudness
+	if loudnessMode != loudnessOff {
+		fmt.Printf("\nComputing loudness (mode=%s)...\n", loudnessMode)
+
+		var byLocation map[string]loudnessResult
+		if loudnessMode == loudnessAnalyze {
+			var locations []string
+			for _, t := range tracks {
+				if t.Location != "" {
+					locations = append(locations, t.Location)
+				}
+			}
+			byLocation = analyzeLoudnessFiles(locations)
+		}
+
+		albumGains := make(map[string][]loudnessResult)
+		for i := range tracks {
+			volumeAdjustmentHint := func() loudnessResult {
+				volAdj := 0
+				if tracks[i].VolumeAdjustment != nil {
+					volAdj = *tracks[i].VolumeAdjustment
+				}
+				return loudnessResult{TrackGain: itunesLoudnessHint(volAdj)}
+			}
+
+			var result loudnessResult
+			switch loudnessMode {
+			case loudnessAnalyze:
+				// Fall back to the iTunes hint for any track whose file-level
+				// analysis was skipped or failed, rather than silently
+				// reporting a zero-value (0 dB, 0 peak) result for it.
+				if r, ok := byLocation[tracks[i].Location]; ok {
+					result = r
+				} else {
+					result = volumeAdjustmentHint()
+				}
+			case loudnessITunes:
+				result = volumeAdjustmentHint()
+			}
+
+			gain, peak := result.TrackGain, result.TrackPeak
+			tracks[i].ReplayGainTrackGain = &gain
+			tracks[i].ReplayGainTrackPeak = &peak
+			loudnessEntries = append(loudnessEntries, LoudnessEntry{
+				TrackID:   tracks[i].ID,
+				Name:      tracks[i].Name,
+				TrackGain: gain,
+				TrackPeak: peak,
+			})
+			albumGains[tracks[i].AlbumSlug] = append(albumGains[tracks[i].AlbumSlug], result)
+		}
+
+		for slug, results := range albumGains {
+			gain, peak := averageLoudness(results)
+			if data, ok := albumsMap[slug]; ok {
+				data.AlbumGain = &gain
+				data.AlbumPeak = &peak
+			}
+			albumLoudness = append(albumLoudness, AlbumLoudness{AlbumSlug: slug, AlbumGain: gain, AlbumPeak: peak})
+		}
+
+		fmt.Printf("Computed loudness for %d tracks\n\n", len(loudnessEntries))
+	}
+
 	// Write chunks
 	chunkSize := 1000
 	totalChunks := (len(tracks) + chunkSize - 1) / chunkSize
@@ -323,13 +453,15 @@ equalizer := SafeStr(row["Equalizer"])
 		artistIndexList = append(artistIndexList, ArtistIndexEntry{
 			Slug:       slug,
 			Name:       data.Name,
+			SortName:   data.SortName,
+			OrderName:  NaturalPad(ToOrderName(data.SortName)),
 			AlbumCount: len(albums),
 			TrackCount: len(data.TrackIDs),
 			Albums:     albums,
 		})
 	}
 	sort.Slice(artistIndexList, func(i, j int) bool {
-		return strings.ToLower(artistIndexList[i].Name) < strings.ToLower(artistIndexList[j].Name)
+		return artistIndexList[i].OrderName < artistIndexList[j].OrderName
 	})
 
 	artistIndex := ArtistIndex{
@@ -350,15 +482,20 @@ equalizer := SafeStr(row["Equalizer"])
 		sort.Strings(artists)
 		
 albumIndexList = append(albumIndexList, AlbumIndexEntry{
-			Slug:        slug,
-			Name:        data.Name,
-			ArtistCount: len(artists),
-			TrackCount:  len(data.TrackIDs),
-			Artists:     artists,
+			Slug:                slug,
+			Name:                data.Name,
+			SortName:            data.SortName,
+			OrderName:           NaturalPad(ToOrderName(data.SortName)),
+			ArtistCount:         len(artists),
+			TrackCount:          len(data.TrackIDs),
+			Artists:             artists,
+			CoverArtURL:         data.CoverArtURL,
+			ReplayGainAlbumGain: data.AlbumGain,
+			ReplayGainAlbumPeak: data.AlbumPeak,
 		})
 	}
 	sort.Slice(albumIndexList, func(i, j int) bool {
-		return strings.ToLower(albumIndexList[i].Name) < strings.ToLower(albumIndexList[j].Name)
+		return albumIndexList[i].OrderName < albumIndexList[j].OrderName
 	})
 
 	albumIndex := AlbumIndex{
@@ -418,6 +555,15 @@ tracksRated := 0
 	writeJSON(filepath.Join(outputDir, "metadata.json"), metadata)
 	fmt.Println("  Wrote metadata.json")
 
+	if loudnessMode != loudnessOff {
+		writeLoudnessOutput(outputDir, loudnessMode, loudnessEntries, albumLoudness)
+		fmt.Println("  Wrote loudness.json")
+	}
+
+	if subsonicFlag() {
+		buildSubsonicData(outputDir, artistIndexList, albumIndexList, tracks)
+	}
+
 	// Summary
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("BUILD COMPLETE!")
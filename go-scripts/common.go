@@ -226,6 +226,98 @@ func SafeStr(val string) string {
 	return strings.TrimSpace(val)
 }
 
+var leadingArticles = []string{"The ", "A ", "An ", "Le ", "La ", "Los ", "Las ", "El "}
+
+// stripLeadingArticle removes one leading article (case-insensitive) from
+// name and returns the remainder, or name unchanged if it has none.
+func stripLeadingArticle(name string) string {
+	for _, article := range leadingArticles {
+		if len(name) > len(article) && strings.EqualFold(name[:len(article)], article) {
+			return name[len(article):]
+		}
+	}
+	return name
+}
+
+// diacriticsTable maps common Latin-1/Latin Extended-A accented runes to
+// their unaccented base letter. It's not exhaustive, but covers the
+// characters that actually show up in artist/album names.
+var diacriticsTable = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ñ': 'n', 'ç': 'c', 'ß': 's',
+}
+
+// StripDiacritics replaces accented letters with their unaccented base,
+// leaving everything else untouched.
+func StripDiacritics(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if base, ok := diacriticsTable[r]; ok {
+			sb.WriteRune(base)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// ToSortName reformats a leading article into a trailing ", Article" form
+// for catalog-style sort display, e.g. "The Beatles" -> "Beatles, The".
+func ToSortName(name string) string {
+	rest := stripLeadingArticle(name)
+	if rest == name {
+		return name
+	}
+	article := strings.TrimSpace(name[:len(name)-len(rest)])
+	return fmt.Sprintf("%s, %s", strings.TrimSpace(rest), article)
+}
+
+var orderNamePunctuation = regexp.MustCompile(`[^\w\s]`)
+var orderNameSpaces = regexp.MustCompile(`\s+`)
+
+// ToOrderName produces a normalized key used purely for sorting: leading
+// articles dropped (not reshuffled), diacritics stripped, punctuation
+// collapsed, lowercased. It's not meant to be displayed.
+func ToOrderName(name string) string {
+	s := stripLeadingArticle(name)
+	s = StripDiacritics(strings.ToLower(s))
+	s = orderNamePunctuation.ReplaceAllString(s, "")
+	s = orderNameSpaces.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
+}
+
+var naturalSortDigits = regexp.MustCompile(`\d+`)
+
+// NaturalPad zero-pads every run of digits in s to a fixed width, so
+// string comparison naturally sorts "Track 2" before "Track 10" instead
+// of treating them as opaque text.
+func NaturalPad(s string) string {
+	return naturalSortDigits.ReplaceAllStringFunc(s, func(digits string) string {
+		if len(digits) >= 6 {
+			return digits
+		}
+		return strings.Repeat("0", 6-len(digits)) + digits
+	})
+}
+
+// ParseITunesDate parses the iTunes CSV "Date Added" format (unpadded
+// M/D/YYYY h:mm AM/PM, e.g. "9/1/2020 10:04 PM") into a time.Time, so
+// callers can compare dates chronologically instead of as raw strings -
+// lexicographic comparison gets month boundaries like 9 vs. 10 backwards.
+// Returns the zero time if s doesn't parse.
+func ParseITunesDate(s string) time.Time {
+	t, err := time.Parse("1/2/2006 3:04 PM", strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 func FormatDuration(seconds int) string {
 	if seconds == 0 {
 		return "0:00"
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const dedupSimilarityThreshold = 0.95
+
+// fingerprintCacheEntry is what's persisted per file so reruns only
+// re-fingerprint files that changed.
+type fingerprintCacheEntry struct {
+	ModTime     int64    `json:"modTime"`
+	Size        int64    `json:"size"`
+	Fingerprint []uint32 `json:"fingerprint"`
+}
+
+type fingerprintCache struct {
+	dir string
+}
+
+func newFingerprintCache(dir string) *fingerprintCache {
+	os.MkdirAll(dir, 0755)
+	return &fingerprintCache{dir: dir}
+}
+
+func (c *fingerprintCache) keyFor(path string, modTime int64, size int64) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d", path, modTime, size)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns a cached fingerprint for path if present and still fresh
+// (same mtime/size), otherwise computes, caches, and returns a new one.
+func (c *fingerprintCache) Get(path string) ([]uint32, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	modTime := info.ModTime().Unix()
+	size := info.Size()
+	key := c.keyFor(path, modTime, size)
+	cachePath := filepath.Join(c.dir, key+".json")
+
+	if data, err := os.ReadFile(cachePath); err == nil {
+		var entry fingerprintCacheEntry
+		if json.Unmarshal(data, &entry) == nil && entry.ModTime == modTime && entry.Size == size {
+			return entry.Fingerprint, nil
+		}
+	}
+
+	// readPCMSamples decodes real audio (via ffmpeg), so the fingerprint
+	// below reflects the actual sound rather than the compressed bytes.
+	samples, err := readPCMSamples(path)
+	if err != nil {
+		return nil, err
+	}
+	fp := fingerprintOf(samples)
+
+	if data, err := json.Marshal(fingerprintCacheEntry{ModTime: modTime, Size: size, Fingerprint: fp}); err == nil {
+		os.WriteFile(cachePath, data, 0644)
+	}
+	return fp, nil
+}
+
+type DedupTrack struct {
+	TrackID string `json:"trackId"`
+	Name    string `json:"name"`
+	Album   string `json:"album"`
+	BitRate int    `json:"bitRate,omitempty"`
+}
+
+type DedupCluster struct {
+	Tracks        []DedupTrack `json:"tracks"`
+	CanonicalID   string       `json:"canonicalId"`
+	Reason        string       `json:"reason"`
+	Similarity    float64      `json:"similarity"`
+	NearDuplicate bool         `json:"nearDuplicate"`
+}
+
+type DedupOutput struct {
+	TotalClusters int            `json:"total_clusters"`
+	Clusters      []DedupCluster `json:"clusters"`
+}
+
+// pickCanonical chooses the "best" track in a duplicate cluster: highest
+// bitrate, then longest duration, then earliest DateAdded.
+func pickCanonical(tracks []WebTrack) (WebTrack, string) {
+	best := tracks[0]
+	for _, t := range tracks[1:] {
+		bestBitRate, tBitRate := 0, 0
+		if best.BitRate != nil {
+			bestBitRate = *best.BitRate
+		}
+		if t.BitRate != nil {
+			tBitRate = *t.BitRate
+		}
+		tAdded := ParseITunesDate(t.DateAdded)
+		bestAdded := ParseITunesDate(best.DateAdded)
+		switch {
+		case tBitRate != bestBitRate:
+			if tBitRate > bestBitRate {
+				best = t
+			}
+		case t.Duration != best.Duration:
+			if t.Duration > best.Duration {
+				best = t
+			}
+		case !tAdded.IsZero() && (bestAdded.IsZero() || tAdded.Before(bestAdded)):
+			best = t
+		}
+	}
+
+	reason := "highest bitrate"
+	if best.BitRate == nil {
+		reason = "longest duration"
+	}
+	return best, reason
+}
+
+// runDedup fingerprints every web-data track and clusters near-duplicates.
+// The fingerprint (see fingerprintOf in find_duplicates.go) is a simplified
+// spectral-band hash in the same spirit as Chromaprint/AcoustID, not the
+// actual Chromaprint library or AcoustID-compatible IDs - don't submit its
+// output to the AcoustID database or expect it to match other tools' fingerprints.
+func runDedup() {
+	outputDir := filepath.Join("..", "web-data")
+	chunksDir := filepath.Join(outputDir, "chunks")
+	cacheDir := filepath.Join(outputDir, "cache", "fingerprints")
+
+	tracks, err := loadAllWebTracks(chunksDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading track chunks: %v\n", err)
+		os.Exit(1)
+	}
+
+	cache := newFingerprintCache(cacheDir)
+
+	type candidate struct {
+		track       WebTrack
+		fingerprint []uint32
+	}
+	var candidates []candidate
+	for _, t := range tracks {
+		if t.Location == "" {
+			continue
+		}
+		fp, err := cache.Get(t.Location)
+		if err != nil || len(fp) == 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{track: t, fingerprint: fp})
+	}
+
+	used := make([]bool, len(candidates))
+	var clusters []DedupCluster
+
+	for i := range candidates {
+		if used[i] {
+			continue
+		}
+		members := []WebTrack{candidates[i].track}
+		var bestSim float64
+
+		for j := i + 1; j < len(candidates); j++ {
+			if used[j] {
+				continue
+			}
+			_, similarity := bestOverlap(candidates[i].fingerprint, candidates[j].fingerprint)
+			if similarity >= dedupSimilarityThreshold {
+				members = append(members, candidates[j].track)
+				used[j] = true
+				if similarity > bestSim {
+					bestSim = similarity
+				}
+			}
+		}
+
+		if len(members) <= 1 {
+			continue
+		}
+		used[i] = true
+
+		canonical, reason := pickCanonical(members)
+		nearDuplicate := false
+		for _, m := range members {
+			if m.Album != canonical.Album {
+				nearDuplicate = true
+				break
+			}
+		}
+
+		var dedupTracks []DedupTrack
+		for _, m := range members {
+			bitRate := 0
+			if m.BitRate != nil {
+				bitRate = *m.BitRate
+			}
+			dedupTracks = append(dedupTracks, DedupTrack{TrackID: m.ID, Name: m.Name, Album: m.Album, BitRate: bitRate})
+		}
+
+		clusters = append(clusters, DedupCluster{
+			Tracks:        dedupTracks,
+			CanonicalID:   canonical.ID,
+			Reason:        reason,
+			Similarity:    bestSim,
+			NearDuplicate: nearDuplicate,
+		})
+	}
+
+	writeJSON(filepath.Join(outputDir, "dedup.json"), DedupOutput{
+		TotalClusters: len(clusters),
+		Clusters:      clusters,
+	})
+
+	fmt.Printf("Fingerprinted %d tracks, found %d duplicate clusters\n", len(candidates), len(clusters))
+}
+
+// loadAllWebTracks reads every tracks-NNN.json chunk in chunksDir and
+// concatenates them back into one slice.
+func loadAllWebTracks(chunksDir string) ([]WebTrack, error) {
+	entries, err := os.ReadDir(chunksDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var tracks []WebTrack
+	for _, name := range names {
+		var chunk ChunkData
+		if err := readJSONFile(filepath.Join(chunksDir, name), &chunk); err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, chunk.Tracks...)
+	}
+	return tracks, nil
+}
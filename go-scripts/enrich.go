@@ -0,0 +1,191 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+var errEnrichBudgetExhausted = errors.New("musicbrainz enrichment budget exhausted")
+
+// musicBrainzResult is the subset of a MusicBrainz recording lookup this
+// tool cares about, plus a derived Cover Art Archive URL.
+type musicBrainzResult struct {
+	RecordingID string `json:"recordingId"`
+	ReleaseID   string `json:"releaseId"`
+	ArtistID    string `json:"artistId"`
+	CoverArtURL string `json:"coverArtUrl,omitempty"`
+}
+
+// musicBrainzEnricher looks up recordings on MusicBrainz, rate-limited to
+// respect their API (1 request/second) and caching every response on disk
+// so a second run with the same budget picks up where the last left off.
+type musicBrainzEnricher struct {
+	httpClient *http.Client
+	cacheDir   string
+	budget     int
+	lastCall   time.Time
+}
+
+func newMusicBrainzEnricher(cacheDir string, budget int) *musicBrainzEnricher {
+	os.MkdirAll(cacheDir, 0755)
+	return &musicBrainzEnricher{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cacheDir:   cacheDir,
+		budget:     budget,
+	}
+}
+
+func (e *musicBrainzEnricher) cacheKey(artist, album, track string) string {
+	sum := sha1.Sum([]byte(artist + "|" + album + "|" + track))
+	return hex.EncodeToString(sum[:])
+}
+
+func (e *musicBrainzEnricher) cachePath(key string) string {
+	return filepath.Join(e.cacheDir, key+".json")
+}
+
+// Lookup resolves a track to MusicBrainz/Cover Art Archive identifiers,
+// preferring a cached response and otherwise spending one unit of budget
+// on a live API call.
+func (e *musicBrainzEnricher) Lookup(artist, album, track string, durationSeconds int) (musicBrainzResult, error) {
+	key := e.cacheKey(artist, album, track)
+	if cached, ok := e.readCache(key); ok {
+		return cached, nil
+	}
+
+	if e.budget <= 0 {
+		return musicBrainzResult{}, errEnrichBudgetExhausted
+	}
+	e.budget--
+
+	e.throttle()
+	result, err := e.queryRecording(artist, album, track, durationSeconds)
+	if err != nil {
+		return musicBrainzResult{}, err
+	}
+
+	e.writeCache(key, result)
+	return result, nil
+}
+
+// throttle sleeps as needed to keep requests at roughly 1/second, per
+// MusicBrainz's API usage guidelines.
+func (e *musicBrainzEnricher) throttle() {
+	elapsed := time.Since(e.lastCall)
+	if elapsed < time.Second {
+		time.Sleep(time.Second - elapsed)
+	}
+	e.lastCall = time.Now()
+}
+
+func (e *musicBrainzEnricher) readCache(key string) (musicBrainzResult, bool) {
+	data, err := os.ReadFile(e.cachePath(key))
+	if err != nil {
+		return musicBrainzResult{}, false
+	}
+	var result musicBrainzResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return musicBrainzResult{}, false
+	}
+	return result, true
+}
+
+func (e *musicBrainzEnricher) writeCache(key string, result musicBrainzResult) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	os.WriteFile(e.cachePath(key), data, 0644)
+}
+
+type mbSearchResponse struct {
+	Recordings []struct {
+		ID       string `json:"id"`
+		Releases []struct {
+			ID string `json:"id"`
+		} `json:"releases"`
+		ArtistCredit []struct {
+			Artist struct {
+				ID string `json:"id"`
+			} `json:"artist"`
+		} `json:"artist-credit"`
+	} `json:"recordings"`
+}
+
+func (e *musicBrainzEnricher) queryRecording(artist, album, track string, durationSeconds int) (musicBrainzResult, error) {
+	query := fmt.Sprintf(`recording:"%s" AND artist:"%s" AND release:"%s"`, track, artist, album)
+	reqURL := "https://musicbrainz.org/ws/2/recording/?" + url.Values{
+		"query": {query},
+		"fmt":   {"json"},
+		"limit": {"1"},
+	}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return musicBrainzResult{}, err
+	}
+	req.Header.Set("User-Agent", "mp3-collection-scripts/1.0 ( github.com/riebschlager/mp3-collection )")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return musicBrainzResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return musicBrainzResult{}, fmt.Errorf("musicbrainz: unexpected status %d", resp.StatusCode)
+	}
+
+	var parsed mbSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return musicBrainzResult{}, err
+	}
+	if len(parsed.Recordings) == 0 {
+		return musicBrainzResult{}, fmt.Errorf("musicbrainz: no match for %s - %s - %s", artist, album, track)
+	}
+
+	rec := parsed.Recordings[0]
+	result := musicBrainzResult{RecordingID: rec.ID}
+	if len(rec.Releases) > 0 {
+		result.ReleaseID = rec.Releases[0].ID
+		result.CoverArtURL = fmt.Sprintf("https://coverartarchive.org/release/%s/front", result.ReleaseID)
+	}
+	if len(rec.ArtistCredit) > 0 {
+		result.ArtistID = rec.ArtistCredit[0].Artist.ID
+	}
+	return result, nil
+}
+
+// shouldEnrich checks for the --enrich flag among the command's arguments.
+func shouldEnrich() bool {
+	for _, arg := range os.Args[2:] {
+		if arg == "--enrich" {
+			return true
+		}
+	}
+	return false
+}
+
+// enrichBudget reads --enrich-budget=N from the command's arguments,
+// defaulting to 500 lookups per run so a partial run is always possible.
+func enrichBudget() int {
+	const defaultBudget = 500
+	for _, arg := range os.Args[2:] {
+		const prefix = "--enrich-budget="
+		if len(arg) > len(prefix) && arg[:len(prefix)] == prefix {
+			if n, err := strconv.Atoi(arg[len(prefix):]); err == nil {
+				return n
+			}
+		}
+	}
+	return defaultBudget
+}
@@ -10,8 +10,52 @@ import (
 )
 
 type AlbumEntry struct {
-	Album   string   `json:"album"`
-	Artists []string `json:"artists"`
+	Album         string   `json:"album"`
+	SortName      string   `json:"sort_name"`
+	OrderName     string   `json:"order_name"`
+	AlbumArtist   string   `json:"album_artist"`
+	IsCompilation bool     `json:"is_compilation"`
+	Artists       []string `json:"artists"`
+}
+
+// albumAgg accumulates everything seen across an album's rows so the
+// album artist / compilation status can be resolved once all rows are in.
+type albumAgg struct {
+	artists      map[string]bool
+	albumArtists map[string]bool
+	compilation  bool
+}
+
+// resolveAlbumArtist picks a single AlbumArtist for the album: the CSV's
+// Album Artist column if it's consistent, otherwise the track artist if
+// all rows agree, otherwise "Various Artists".
+func (a *albumAgg) resolveAlbumArtist() string {
+	if len(a.albumArtists) == 1 {
+		for v := range a.albumArtists {
+			return v
+		}
+	}
+	if len(a.artists) == 1 {
+		for v := range a.artists {
+			return v
+		}
+	}
+	return "Various Artists"
+}
+
+func (a *albumAgg) isCompilation() bool {
+	return a.compilation || len(a.artists) > 1
+}
+
+// IsTruthy interprets the common boolean spellings iTunes CSV exports use
+// for checkbox columns like "Compilation".
+func IsTruthy(val string) bool {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "1", "true", "yes":
+		return true
+	default:
+		return false
+	}
 }
 
 type AlbumsOutput struct {
@@ -35,40 +79,55 @@ rows, err := ReadCSV(csvPath)
 		os.Exit(1)
 	}
 
-	// Map album -> set of artists
-	albumsMap := make(map[string]map[string]bool)
+	// Map album -> aggregated artist/compilation info across its rows
+	albumsMap := make(map[string]*albumAgg)
 
 	for _, row := range rows {
 		album := SanitizeAlbumName(row["Album"])
 		artist := SanitizeArtistName(row["Artist"])
+		albumArtist := SanitizeArtistName(row["Album Artist"])
 
-		if album != "" && IsValidName(album) {
-			if _, ok := albumsMap[album]; !ok {
-				albumsMap[album] = make(map[string]bool)
-			}
-			if artist != "" {
-				albumsMap[album][artist] = true
-			}
+		if album == "" || !IsValidName(album) {
+			continue
+		}
+
+		agg, ok := albumsMap[album]
+		if !ok {
+			agg = &albumAgg{artists: make(map[string]bool), albumArtists: make(map[string]bool)}
+			albumsMap[album] = agg
+		}
+		if artist != "" {
+			agg.artists[artist] = true
+		}
+		if albumArtist != "" {
+			agg.albumArtists[albumArtist] = true
+		}
+		if IsTruthy(row["Compilation"]) {
+			agg.compilation = true
 		}
 	}
 
 	// Convert to list
 	var albumList []AlbumEntry
-	for album, artistSet := range albumsMap {
+	for album, agg := range albumsMap {
 		var artists []string
-		for art := range artistSet {
+		for art := range agg.artists {
 			artists = append(artists, art)
 		}
 		sort.Strings(artists)
 		albumList = append(albumList, AlbumEntry{
-			Album:   album,
-			Artists: artists,
+			Album:         album,
+			SortName:      ToSortName(album),
+			OrderName:     ToOrderName(album),
+			AlbumArtist:   agg.resolveAlbumArtist(),
+			IsCompilation: agg.isCompilation(),
+			Artists:       artists,
 		})
 	}
 
-	// Sort albums by name
+	// Sort albums by order name so articles and diacritics collate correctly
 	sort.Slice(albumList, func(i, j int) bool {
-		return albumList[i].Album < albumList[j].Album
+		return albumList[i].OrderName < albumList[j].OrderName
 	})
 
 	outputData := AlbumsOutput{
@@ -10,8 +10,10 @@ import (
 )
 
 type ArtistEntry struct {
-	Artist string   `json:"artist"`
-	Albums []string `json:"albums"`
+	Artist    string   `json:"artist"`
+	SortName  string   `json:"sort_name"`
+	OrderName string   `json:"order_name"`
+	Albums    []string `json:"albums"`
 }
 
 type ArtistsOutput struct {
@@ -60,14 +62,16 @@ func runExtractArtists() {
 		}
 		sort.Strings(albums)
 		artistList = append(artistList, ArtistEntry{
-			Artist: artist,
-			Albums: albums,
+			Artist:    artist,
+			SortName:  ToSortName(artist),
+			OrderName: ToOrderName(artist),
+			Albums:    albums,
 		})
 	}
 
-	// Sort artists by name
+	// Sort artists by order name so articles and diacritics collate correctly
 	sort.Slice(artistList, func(i, j int) bool {
-		return artistList[i].Artist < artistList[j].Artist
+		return artistList[i].OrderName < artistList[j].OrderName
 	})
 
 	outputData := ArtistsOutput{
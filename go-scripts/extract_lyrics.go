@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LyricLine is a single line of lyrics, optionally timestamped.
+type LyricLine struct {
+	TimeMs int    `json:"time_ms,omitempty"`
+	Text   string `json:"text"`
+}
+
+// Lyrics is the common shape both synchronized (LRC) and plain lyrics are
+// parsed into, regardless of where they came from.
+type Lyrics struct {
+	Synced bool        `json:"synced"`
+	Lines  []LyricLine `json:"lines"`
+}
+
+type LyricsEntry struct {
+	Track      string `json:"track"`
+	Artist     string `json:"artist"`
+	Album      string `json:"album"`
+	TrackSlug  string `json:"track_slug"`
+	ArtistSlug string `json:"artist_slug"`
+	AlbumSlug  string `json:"album_slug"`
+	Lyrics     Lyrics `json:"lyrics"`
+}
+
+type LyricsOutput struct {
+	TotalTracks int           `json:"total_tracks"`
+	Lyrics      []LyricsEntry `json:"lyrics"`
+}
+
+// lrcTimestamp matches one [mm:ss.xx], [mm:ss.xxx] or [mm:ss.x] tag. LRC
+// allows several of these in a row before the lyric text, for lines that
+// repeat at multiple points in the song.
+var lrcTimestamp = regexp.MustCompile(`^\[(\d{1,3}):(\d{2})(?:[.:](\d{1,3}))?\]`)
+
+// ParseLyrics turns raw lyrics text into a Lyrics value, auto-detecting
+// whether it's LRC-timestamped or plain.
+func ParseLyrics(raw string) Lyrics {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	lines := strings.Split(raw, "\n")
+
+	var out Lyrics
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			continue
+		}
+
+		var timestamps []int
+		rest := line
+		for {
+			m := lrcTimestamp.FindStringSubmatch(rest)
+			if m == nil {
+				break
+			}
+			timestamps = append(timestamps, lrcTimeMs(m[1], m[2], m[3]))
+			rest = rest[len(m[0]):]
+		}
+
+		if len(timestamps) == 0 {
+			out.Lines = append(out.Lines, LyricLine{Text: strings.TrimSpace(line)})
+			continue
+		}
+
+		out.Synced = true
+		text := strings.TrimSpace(rest)
+		for _, ms := range timestamps {
+			out.Lines = append(out.Lines, LyricLine{TimeMs: ms, Text: text})
+		}
+	}
+
+	if out.Synced {
+		sortLyricLines(out.Lines)
+	}
+	return out
+}
+
+func sortLyricLines(lines []LyricLine) {
+	for i := 1; i < len(lines); i++ {
+		for j := i; j > 0 && lines[j].TimeMs < lines[j-1].TimeMs; j-- {
+			lines[j], lines[j-1] = lines[j-1], lines[j]
+		}
+	}
+}
+
+// lrcTimeMs converts [mm:ss.frac] components to milliseconds, scaling the
+// fractional part whether it was given as centiseconds or milliseconds.
+func lrcTimeMs(minutes, seconds, frac string) int {
+	m, _ := strconv.Atoi(minutes)
+	s, _ := strconv.Atoi(seconds)
+	ms := 0
+	if frac != "" {
+		n, _ := strconv.Atoi(frac)
+		switch len(frac) {
+		case 1:
+			ms = n * 100
+		case 2:
+			ms = n * 10
+		default:
+			ms = n
+		}
+	}
+	return m*60000 + s*1000 + ms
+}
+
+// FormatLRC renders synced lyrics back out as a standard LRC file body,
+// using [mm:ss.xx] (centisecond) timestamps.
+func FormatLRC(l Lyrics) string {
+	var sb strings.Builder
+	for _, line := range l.Lines {
+		if l.Synced {
+			minutes := line.TimeMs / 60000
+			seconds := (line.TimeMs % 60000) / 1000
+			centis := (line.TimeMs % 1000) / 10
+			fmt.Fprintf(&sb, "[%02d:%02d.%02d]%s\n", minutes, seconds, centis, line.Text)
+		} else {
+			fmt.Fprintf(&sb, "%s\n", line.Text)
+		}
+	}
+	return sb.String()
+}
+
+func runExtractLyrics() {
+	csvPath := filepath.Join("..", "archive", "compiled_itunes_library.csv")
+	outPath := filepath.Join("..", "data", "lyrics.json")
+	lrcDir := filepath.Join("..", "data", "lyrics")
+
+	writeSidecars := false
+	musicRoot := ""
+	for _, arg := range os.Args[2:] {
+		switch {
+		case arg == "--lrc-files":
+			writeSidecars = true
+		case strings.HasPrefix(arg, "--music-root="):
+			musicRoot = strings.TrimPrefix(arg, "--music-root=")
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	var entries []LyricsEntry
+	if musicRoot != "" {
+		entries = extractLyricsFromTags(musicRoot)
+	} else {
+		entries = extractLyricsFromCSV(csvPath)
+	}
+
+	if writeSidecars {
+		for _, entry := range entries {
+			if err := writeLRCSidecar(lrcDir, entry); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing lrc for %s: %v\n", entry.Track, err)
+			}
+		}
+	}
+
+	writeJSON(outPath, LyricsOutput{
+		TotalTracks: len(entries),
+		Lyrics:      entries,
+	})
+
+	fmt.Printf("Wrote %d lyrics entries to %s\n", len(entries), outPath)
+	if writeSidecars {
+		fmt.Printf("Wrote .lrc sidecars under %s\n", lrcDir)
+	}
+}
+
+// extractLyricsFromCSV pulls lyrics out of the iTunes export's Lyrics
+// column, the original source for this tool.
+func extractLyricsFromCSV(csvPath string) []LyricsEntry {
+	rows, err := ReadCSV(csvPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading CSV: %v\n", err)
+		os.Exit(1)
+	}
+
+	var entries []LyricsEntry
+	for _, row := range rows {
+		raw := row["Lyrics"]
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		trackName := strings.TrimSpace(row["Name"])
+		artist := SanitizeArtistName(row["Artist"])
+		album := strings.TrimSpace(row["Album"])
+		if trackName == "" || !IsValidName(artist) || !IsValidName(album) {
+			continue
+		}
+
+		entries = append(entries, LyricsEntry{
+			Track:      trackName,
+			Artist:     artist,
+			Album:      album,
+			TrackSlug:  Slugify(trackName),
+			ArtistSlug: Slugify(artist),
+			AlbumSlug:  Slugify(album),
+			Lyrics:     ParseLyrics(raw),
+		})
+	}
+	return entries
+}
+
+// extractLyricsFromTags walks root and pulls embedded USLT/SYLT lyrics out
+// of each file's ID3 tags, via the same TagReader scan-library uses.
+func extractLyricsFromTags(root string) []LyricsEntry {
+	var reader TagReader = id3v2TagReader{}
+	var entries []LyricsEntry
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !scanLibraryExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		meta, err := reader.Read(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+			return nil
+		}
+		if len(meta.Lyrics.Lines) == 0 {
+			return nil
+		}
+
+		trackName := strings.TrimSpace(meta.Title)
+		artist := SanitizeArtistName(meta.Artist)
+		album := SanitizeAlbumName(meta.Album)
+		if trackName == "" || !IsValidName(artist) || !IsValidName(album) {
+			return nil
+		}
+
+		entries = append(entries, LyricsEntry{
+			Track:      trackName,
+			Artist:     artist,
+			Album:      album,
+			TrackSlug:  Slugify(trackName),
+			ArtistSlug: Slugify(artist),
+			AlbumSlug:  Slugify(album),
+			Lyrics:     meta.Lyrics,
+		})
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error walking %s: %v\n", root, err)
+		os.Exit(1)
+	}
+	return entries
+}
+
+func writeLRCSidecar(lrcDir string, entry LyricsEntry) error {
+	dir := filepath.Join(lrcDir, entry.ArtistSlug, entry.AlbumSlug)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, entry.TrackSlug+".lrc")
+	return os.WriteFile(path, []byte(FormatLRC(entry.Lyrics)), 0644)
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	fingerprintSampleRate = 11025
+	fingerprintFrameSize  = 4096
+	fingerprintHopSize    = 2048
+	fingerprintBands      = 12
+	// duplicateHammingThreshold is the max differing bits allowed per hash
+	// for two frames to be considered a match.
+	duplicateHammingThreshold = 3
+	// duplicateMinOverlapFrames requires at least ~30s of matching audio
+	// (at the hop size/sample rate above) before two tracks count as dupes.
+	duplicateMinOverlapFrames = (30 * fingerprintSampleRate) / fingerprintHopSize
+)
+
+type DuplicateTrack struct {
+	Location string `json:"location"`
+	Name     string `json:"name"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+}
+
+type DuplicateCluster struct {
+	Tracks     []DuplicateTrack `json:"tracks"`
+	Similarity float64          `json:"similarity"`
+}
+
+type DuplicatesOutput struct {
+	TotalClusters int                `json:"total_clusters"`
+	Clusters      []DuplicateCluster `json:"clusters"`
+}
+
+// readPCMSamples decodes path into mono samples in [-1, 1] at
+// fingerprintSampleRate by shelling out to ffmpeg, so the fingerprint is
+// computed from actual decoded audio and catches re-encodes/alternate rips
+// of the same recording, not just byte-identical files. Returns an error
+// (rather than fabricated data) if ffmpeg isn't on PATH or the file won't
+// decode; callers skip files that error.
+func readPCMSamples(path string) ([]float64, error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, fmt.Errorf("ffmpeg not found on PATH: %w", err)
+	}
+
+	cmd := exec.Command("ffmpeg",
+		"-v", "error",
+		"-i", path,
+		"-ac", "1",
+		"-ar", strconv.Itoa(fingerprintSampleRate),
+		"-f", "s16le",
+		"-")
+	pcm, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg decode of %s: %w", path, err)
+	}
+
+	samples := make([]float64, len(pcm)/2)
+	for i := range samples {
+		v := int16(binary.LittleEndian.Uint16(pcm[i*2 : i*2+2]))
+		samples[i] = float64(v) / 32768.0
+	}
+	return samples, nil
+}
+
+// fingerprintOf reduces the audio to a sequence of 32-bit hashes: each
+// frame's spectrum is collapsed into fingerprintBands log-spaced energy
+// bands, and each hash bit is the sign of that band's change versus the
+// previous frame.
+func fingerprintOf(samples []float64) []uint32 {
+	if len(samples) < fingerprintFrameSize {
+		return nil
+	}
+
+	var hashes []uint32
+	var prevBands [fingerprintBands]float64
+	first := true
+
+	for start := 0; start+fingerprintFrameSize <= len(samples); start += fingerprintHopSize {
+		frame := samples[start : start+fingerprintFrameSize]
+		bands := bandEnergies(frame)
+
+		if first {
+			prevBands = bands
+			first = false
+			continue
+		}
+
+		var hash uint32
+		for i := 0; i < fingerprintBands; i++ {
+			if bands[i] > prevBands[i] {
+				hash |= 1 << uint(i)
+			}
+		}
+		hashes = append(hashes, hash)
+		prevBands = bands
+	}
+	return hashes
+}
+
+// bandEnergies windows frame, takes its magnitude spectrum via FFT, and
+// reduces that spectrum to fingerprintBands log-spaced frequency bands,
+// returning the mean magnitude of each. fingerprintFrameSize must be a
+// power of two for fft to apply.
+func bandEnergies(frame []float64) [fingerprintBands]float64 {
+	n := len(frame)
+	spectrum := make([]complex128, n)
+	for i, v := range frame {
+		// Hann window to reduce spectral leakage from the frame's hard edges.
+		w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		spectrum[i] = complex(v*w, 0)
+	}
+	fft(spectrum)
+
+	// Real input produces a conjugate-symmetric spectrum, so only the
+	// first half carries unique frequency information.
+	half := n / 2
+
+	var bands [fingerprintBands]float64
+	edges := make([]int, fingerprintBands+1)
+	for i := 0; i <= fingerprintBands; i++ {
+		frac := float64(i) / float64(fingerprintBands)
+		edges[i] = int(frac * frac * float64(half))
+	}
+	edges[fingerprintBands] = half
+
+	for b := 0; b < fingerprintBands; b++ {
+		lo, hi := edges[b], edges[b+1]
+		if hi <= lo {
+			continue
+		}
+		var sum float64
+		for _, c := range spectrum[lo:hi] {
+			sum += cmplx.Abs(c)
+		}
+		bands[b] = sum / float64(hi-lo)
+	}
+	return bands
+}
+
+// fft computes the discrete Fourier transform of x in place using the
+// recursive radix-2 Cooley-Tukey algorithm. len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+
+	even := make([]complex128, n/2)
+	odd := make([]complex128, n/2)
+	for i := 0; i < n/2; i++ {
+		even[i] = x[2*i]
+		odd[i] = x[2*i+1]
+	}
+	fft(even)
+	fft(odd)
+
+	for k := 0; k < n/2; k++ {
+		twiddle := cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n)) * odd[k]
+		x[k] = even[k] + twiddle
+		x[k+n/2] = even[k] - twiddle
+	}
+}
+
+// bestOverlap slides b against a and returns the longest run of frames
+// where the Hamming distance stays within duplicateHammingThreshold, plus
+// the fraction of matching bits across that run.
+func bestOverlap(a, b []uint32) (frames int, similarity float64) {
+	for offset := -len(b) + 1; offset < len(a); offset++ {
+		run := 0
+		matchingBits := 0
+		totalBits := 0
+		for i := 0; i < len(b); i++ {
+			ai := i + offset
+			if ai < 0 || ai >= len(a) {
+				if run > frames {
+					frames, similarity = run, ratio(matchingBits, totalBits)
+				}
+				run, matchingBits, totalBits = 0, 0, 0
+				continue
+			}
+			diff := bits.OnesCount32(a[ai] ^ b[i])
+			totalBits += 32
+			matchingBits += 32 - diff
+			if diff <= duplicateHammingThreshold {
+				run++
+			} else {
+				if run > frames {
+					frames, similarity = run, ratio(matchingBits, totalBits)
+				}
+				run, matchingBits, totalBits = 0, 0, 0
+			}
+		}
+		if run > frames {
+			frames, similarity = run, ratio(matchingBits, totalBits)
+		}
+	}
+	return frames, similarity
+}
+
+func ratio(matching, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(matching) / float64(total)
+}
+
+func runFindDuplicates() {
+	csvPath := filepath.Join("..", "archive", "compiled_itunes_library.csv")
+	outPath := filepath.Join("..", "data", "duplicates.json")
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	rows, err := ReadCSV(csvPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading CSV: %v\n", err)
+		os.Exit(1)
+	}
+
+	type candidate struct {
+		track       DuplicateTrack
+		fingerprint []uint32
+	}
+
+	var candidates []candidate
+	for _, row := range rows {
+		location := SafeStr(row["Location"])
+		if location == "" {
+			continue
+		}
+		if _, err := os.Stat(location); err != nil {
+			continue
+		}
+
+		samples, err := readPCMSamples(location)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", location, err)
+			continue
+		}
+
+		candidates = append(candidates, candidate{
+			track: DuplicateTrack{
+				Location: location,
+				Name:     strings.TrimSpace(row["Name"]),
+				Artist:   SanitizeArtistName(row["Artist"]),
+				Album:    SanitizeAlbumName(row["Album"]),
+			},
+			fingerprint: fingerprintOf(samples),
+		})
+	}
+
+	used := make([]bool, len(candidates))
+	var clusters []DuplicateCluster
+
+	for i := range candidates {
+		if used[i] || len(candidates[i].fingerprint) == 0 {
+			continue
+		}
+		cluster := DuplicateCluster{Tracks: []DuplicateTrack{candidates[i].track}}
+		var bestSim float64
+
+		for j := i + 1; j < len(candidates); j++ {
+			if used[j] || len(candidates[j].fingerprint) == 0 {
+				continue
+			}
+			overlapFrames, similarity := bestOverlap(candidates[i].fingerprint, candidates[j].fingerprint)
+			if overlapFrames >= duplicateMinOverlapFrames {
+				cluster.Tracks = append(cluster.Tracks, candidates[j].track)
+				used[j] = true
+				if similarity > bestSim {
+					bestSim = similarity
+				}
+			}
+		}
+
+		if len(cluster.Tracks) > 1 {
+			used[i] = true
+			cluster.Similarity = bestSim
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	writeJSON(outPath, DuplicatesOutput{
+		TotalClusters: len(clusters),
+		Clusters:      clusters,
+	})
+
+	fmt.Printf("Scanned %d files, found %d duplicate clusters\n", len(candidates), len(clusters))
+	fmt.Printf("Wrote %s\n", outPath)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// loudnessMode controls how (or whether) ReplayGain-style fields get
+// computed for build-web-data.
+type loudnessMode string
+
+const (
+	loudnessOff     loudnessMode = "off"
+	loudnessITunes  loudnessMode = "itunes"
+	loudnessAnalyze loudnessMode = "analyze"
+)
+
+// loudnessResult holds the per-track ReplayGain-equivalent values, in dB
+// relative to a reference loudness and linear peak amplitude (0..1).
+type loudnessResult struct {
+	TrackGain float64
+	TrackPeak float64
+}
+
+type LoudnessEntry struct {
+	TrackID   string  `json:"trackId"`
+	Name      string  `json:"name"`
+	TrackGain float64 `json:"trackGainDb"`
+	TrackPeak float64 `json:"trackPeak"`
+}
+
+type LoudnessOutput struct {
+	Mode   string          `json:"mode"`
+	Tracks []LoudnessEntry `json:"tracks"`
+	Albums []AlbumLoudness `json:"albums"`
+}
+
+type AlbumLoudness struct {
+	AlbumSlug string  `json:"albumSlug"`
+	AlbumGain float64 `json:"albumGainDb"`
+	AlbumPeak float64 `json:"albumPeak"`
+}
+
+// loudnessModeFlag reads --loudness=off|itunes|analyze from the command's
+// arguments, defaulting to off.
+func loudnessModeFlag() loudnessMode {
+	const prefix = "--loudness="
+	for _, arg := range os.Args[2:] {
+		if len(arg) > len(prefix) && arg[:len(prefix)] == prefix {
+			mode := loudnessMode(arg[len(prefix):])
+			if mode == loudnessOff || mode == loudnessITunes || mode == loudnessAnalyze {
+				return mode
+			}
+		}
+	}
+	return loudnessOff
+}
+
+// itunesLoudnessHint converts iTunes' -255..255 Volume Adjustment slider
+// into an approximate dB gain, treating the full range as roughly ±12dB.
+func itunesLoudnessHint(volumeAdjustment int) float64 {
+	return float64(volumeAdjustment) / 255.0 * 12.0
+}
+
+// analyzeLoudnessFiles computes a ReplayGain-style gain/peak pair per
+// location using a worker pool, running ffmpeg's ebur128 filter over each
+// file for a real EBU R128 integrated-loudness measurement.
+func analyzeLoudnessFiles(locations []string) map[string]loudnessResult {
+	results := make(map[string]loudnessResult, len(locations))
+	var mu sync.Mutex
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	workerCount := runtime.NumCPU()
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
+	for w := 0; w < workerCount; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for location := range jobs {
+				result, err := analyzeLoudnessFile(location)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				results[location] = result
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, location := range locations {
+		jobs <- location
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// ebur128IntegratedRe and ebur128PeakRe pull the integrated loudness and
+// true peak lines out of ffmpeg's ebur128 filter summary, e.g.:
+//
+//	  Integrated loudness:
+//	    I:         -14.2 LUFS
+//	  True peak:
+//	    Peak:       -1.3 dBFS
+var (
+	ebur128IntegratedRe = regexp.MustCompile(`(?m)^\s*I:\s*(-?[\d.]+) LUFS`)
+	ebur128PeakRe       = regexp.MustCompile(`(?m)^\s*Peak:\s*(-?[\d.]+) dBFS`)
+)
+
+// referenceLUFS is the ReplayGain 2.0 reference loudness; TrackGain is how
+// far a track's measured integrated loudness is from this target.
+const referenceLUFS = -18.0
+
+func analyzeLoudnessFile(location string) (loudnessResult, error) {
+	cmd := exec.Command("ffmpeg", "-i", location, "-af", "ebur128=peak=true", "-f", "null", "-")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return loudnessResult{}, fmt.Errorf("ffmpeg ebur128 analysis of %s: %w", location, err)
+	}
+
+	integratedLUFS, peakDB, err := parseEBUR128Summary(stderr.String())
+	if err != nil {
+		return loudnessResult{}, fmt.Errorf("parsing ebur128 output for %s: %w", location, err)
+	}
+
+	gain := referenceLUFS - integratedLUFS
+	peak := math.Pow(10, peakDB/20)
+	return loudnessResult{TrackGain: gain, TrackPeak: peak}, nil
+}
+
+// parseEBUR128Summary extracts the integrated loudness (LUFS) and true
+// peak (dBFS) from ffmpeg's ebur128 filter summary.
+func parseEBUR128Summary(output string) (integratedLUFS, peakDB float64, err error) {
+	m := ebur128IntegratedRe.FindStringSubmatch(output)
+	if m == nil {
+		return 0, 0, fmt.Errorf("no integrated loudness found in ffmpeg output")
+	}
+	integratedLUFS, err = strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if m := ebur128PeakRe.FindStringSubmatch(output); m != nil {
+		peakDB, _ = strconv.ParseFloat(m[1], 64)
+	}
+	return integratedLUFS, peakDB, nil
+}
+
+// averageLoudness combines the per-track results for an album into a
+// single album-level gain (mean) and peak (max).
+func averageLoudness(results []loudnessResult) (gain float64, peak float64) {
+	if len(results) == 0 {
+		return 0, 0
+	}
+	var sumGain float64
+	for _, r := range results {
+		sumGain += r.TrackGain
+		if r.TrackPeak > peak {
+			peak = r.TrackPeak
+		}
+	}
+	return sumGain / float64(len(results)), peak
+}
+
+func writeLoudnessOutput(outputDir string, mode loudnessMode, entries []LoudnessEntry, albums []AlbumLoudness) {
+	writeJSON(filepath.Join(outputDir, "loudness.json"), LoudnessOutput{
+		Mode:   string(mode),
+		Tracks: entries,
+		Albums: albums,
+	})
+}
@@ -22,6 +22,18 @@ func main() {
 		runExtractAlbums()
 	case "build-web-data":
 		runBuildWebData()
+	case "scan-library":
+		runScanLibrary()
+	case "extract-lyrics":
+		runExtractLyrics()
+	case "serve":
+		runServe()
+	case "find-duplicates":
+		runFindDuplicates()
+	case "serve-subsonic":
+		runServeSubsonic()
+	case "dedup":
+		runDedup()
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		printUsage()
@@ -35,5 +47,11 @@ func printUsage() {
 	fmt.Println("  extract-tracks    Extract tracks to data/tracks.json")
 	fmt.Println("  extract-artists   Extract artists to data/artists.json")
 	fmt.Println("  extract-albums    Extract albums to data/albums.json")
-	fmt.Println("  build-web-data    Build optimized web data to web-data/")
+	fmt.Println("  build-web-data [--source=itunes-csv|fs|subsonic] [--enrich] [--enrich-budget=N] [--loudness=off|itunes|analyze] [--subsonic]  Build optimized web data to web-data/")
+	fmt.Println("  scan-library <dir>  Scan mp3 files directly and regenerate data/*.json from their tags")
+	fmt.Println("  extract-lyrics [--lrc-files] [--music-root=<dir>]  Extract lyrics to data/lyrics.json (and .lrc sidecars); from the CSV Lyrics column, or embedded USLT/SYLT tags if --music-root is given")
+	fmt.Println("  serve [addr]      Serve data/*.json as an HTTP API (default :8080)")
+	fmt.Println("  find-duplicates   Fingerprint tracks and group likely duplicates into data/duplicates.json")
+	fmt.Println("  serve-subsonic [addr]  Serve web-data/subsonic/*.json as a Subsonic-compatible API (default :8080)")
+	fmt.Println("  dedup             Fingerprint web-data tracks (with caching) and pick a canonical copy per duplicate cluster")
 }
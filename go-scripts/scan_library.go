@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TagMetadata is the normalized set of tag fields a TagReader can pull out
+// of an audio file. Backends fill in whatever they support and leave the
+// rest at the zero value.
+type TagMetadata struct {
+	Title               string
+	Artist              string
+	Album               string
+	AlbumArtist         string
+	Genre               string
+	Year                int
+	TrackNumber         int
+	TrackCount          int
+	DiscNumber          int
+	DiscCount           int
+	Composer            string
+	MusicBrainzTrackID  string
+	MusicBrainzAlbumID  string
+	MusicBrainzArtistID string
+	ArtworkPath         string
+	Lyrics              Lyrics
+}
+
+// TagReader reads embedded tag metadata from a single audio file. It's the
+// extension point for swapping in different backends (ID3, Vorbis comments,
+// MP4 atoms, etc.) without touching the scanning/output logic below.
+type TagReader interface {
+	Read(path string) (TagMetadata, error)
+}
+
+// id3v2TagReader reads the text frames out of an ID3v2.3/2.4 header. It
+// doesn't attempt full spec coverage (no ID3v2.2, no unsynchronization) -
+// just the frames this tool needs.
+type id3v2TagReader struct{}
+
+func (id3v2TagReader) Read(path string) (TagMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TagMetadata{}, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return TagMetadata{}, err
+	}
+	if string(header[0:3]) != "ID3" {
+		return TagMetadata{}, fmt.Errorf("%s: no ID3v2 header", path)
+	}
+	majorVersion := header[3]
+	tagSize := synchsafeInt(header[6:10])
+
+	body := make([]byte, tagSize)
+	if _, err := io.ReadFull(f, body); err != nil {
+		return TagMetadata{}, err
+	}
+
+	frames := make(map[string]string)
+	var artworkFound bool
+	var uslt, sylt Lyrics
+	pos := 0
+	for pos+10 <= len(body) {
+		frameID := string(body[pos : pos+4])
+		if frameID == "\x00\x00\x00\x00" {
+			break
+		}
+
+		var frameSize int
+		if majorVersion >= 4 {
+			frameSize = synchsafeInt(body[pos+4 : pos+8])
+		} else {
+			frameSize = int(binary.BigEndian.Uint32(body[pos+4 : pos+8]))
+		}
+		pos += 10
+		if pos+frameSize > len(body) || frameSize < 0 {
+			break
+		}
+		frameData := body[pos : pos+frameSize]
+		pos += frameSize
+
+		switch {
+		case frameID == "APIC":
+			artworkFound = true
+		case frameID == "USLT":
+			uslt = parseUSLT(frameData)
+		case frameID == "SYLT":
+			sylt = parseSYLT(frameData)
+		case strings.HasPrefix(frameID, "T") || frameID == "UFID":
+			frames[frameID] = decodeTextFrame(frameData)
+		}
+	}
+
+	meta := TagMetadata{
+		Title:       frames["TIT2"],
+		Artist:      frames["TPE1"],
+		Album:       frames["TALB"],
+		AlbumArtist: frames["TPE2"],
+		Genre:       frames["TCON"],
+		Composer:    frames["TCOM"],
+		Year:        SafeInt(frames["TYER"]),
+	}
+	if meta.Year == 0 {
+		meta.Year = SafeInt(firstToken(frames["TDRC"], '-'))
+	}
+	meta.TrackNumber, meta.TrackCount = splitSlashPair(frames["TRCK"])
+	meta.DiscNumber, meta.DiscCount = splitSlashPair(frames["TPOS"])
+	meta.MusicBrainzTrackID = frames["UFID"]
+	if artworkFound {
+		meta.ArtworkPath = path
+	}
+	meta.Lyrics = uslt
+	if sylt.Synced {
+		meta.Lyrics = sylt
+	}
+	return meta, nil
+}
+
+func synchsafeInt(b []byte) int {
+	return int(b[0])<<21 | int(b[1])<<14 | int(b[2])<<7 | int(b[3])
+}
+
+// decodeTextFrame strips the leading text-encoding byte and any trailing
+// NUL padding, then decodes the remaining bytes per the declared encoding
+// (Latin-1 or UTF-16 with BOM); anything stranger is returned as-is.
+func decodeTextFrame(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	encoding := data[0]
+	data = data[1:]
+
+	switch encoding {
+	case 1, 2: // UTF-16 with or without BOM
+		if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
+			data = data[2:]
+		} else if len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF {
+			data = data[2:]
+		}
+		var sb strings.Builder
+		for i := 0; i+1 < len(data); i += 2 {
+			r := rune(data[i]) | rune(data[i+1])<<8
+			if r == 0 {
+				break
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String()
+	default: // 0: ISO-8859-1 (Latin-1)
+		data = bytes.TrimRight(data, "\x00")
+		var sb strings.Builder
+		for _, b := range data {
+			// Latin-1 code points map 1:1 onto the first 256 Unicode code
+			// points, so each byte is its own rune - passing the bytes
+			// through as-is would instead decode them as (invalid) UTF-8.
+			sb.WriteRune(rune(b))
+		}
+		return sb.String()
+	}
+}
+
+// nullTerminatorWidth returns how many 0x00 bytes terminate a string in the
+// given ID3v2 text encoding: 2 for UTF-16 (1 or 2), 1 for single-byte
+// encodings.
+func nullTerminatorWidth(encoding byte) int {
+	if encoding == 1 || encoding == 2 {
+		return 2
+	}
+	return 1
+}
+
+// splitNullTerminated splits data at the first null terminator sized for
+// encoding, returning the bytes before it and whatever follows. rest is nil
+// if no terminator was found.
+func splitNullTerminated(data []byte, encoding byte) (field, rest []byte) {
+	width := nullTerminatorWidth(encoding)
+	for i := 0; i+width <= len(data); i += width {
+		zero := true
+		for j := 0; j < width; j++ {
+			if data[i+j] != 0 {
+				zero = false
+				break
+			}
+		}
+		if zero {
+			return data[:i], data[i+width:]
+		}
+	}
+	return data, nil
+}
+
+// parseUSLT decodes a USLT (unsynchronized lyrics) frame: encoding byte,
+// 3-byte language code, null-terminated content descriptor, then the lyric
+// text itself in the declared encoding.
+func parseUSLT(data []byte) Lyrics {
+	if len(data) < 4 {
+		return Lyrics{}
+	}
+	encoding := data[0]
+	_, rest := splitNullTerminated(data[4:], encoding)
+	text := decodeTextFrame(append([]byte{encoding}, rest...))
+	if strings.TrimSpace(text) == "" {
+		return Lyrics{}
+	}
+	return ParseLyrics(text)
+}
+
+// parseSYLT decodes a SYLT (synchronized lyrics) frame: encoding byte,
+// 3-byte language code, 1-byte timestamp format, 1-byte content type,
+// null-terminated content descriptor, then (text, 4-byte big-endian
+// timestamp) pairs. Only millisecond timestamps (format 2) are supported;
+// MPEG-frame-based timestamps (format 1) are skipped.
+func parseSYLT(data []byte) Lyrics {
+	if len(data) < 6 {
+		return Lyrics{}
+	}
+	encoding := data[0]
+	timestampFormat := data[4]
+	if timestampFormat != 2 {
+		return Lyrics{}
+	}
+	_, body := splitNullTerminated(data[6:], encoding)
+
+	var lines []LyricLine
+	for len(body) > 0 {
+		text, rest := splitNullTerminated(body, encoding)
+		if rest == nil || len(rest) < 4 {
+			break
+		}
+		timeMs := int(binary.BigEndian.Uint32(rest[:4]))
+		lines = append(lines, LyricLine{
+			TimeMs: timeMs,
+			Text:   strings.TrimSpace(decodeTextFrame(append([]byte{encoding}, text...))),
+		})
+		body = rest[4:]
+	}
+	if len(lines) == 0 {
+		return Lyrics{}
+	}
+	sortLyricLines(lines)
+	return Lyrics{Synced: true, Lines: lines}
+}
+
+func firstToken(s string, sep byte) string {
+	if idx := strings.IndexByte(s, sep); idx >= 0 {
+		return s[:idx]
+	}
+	return s
+}
+
+func splitSlashPair(s string) (num int, total int) {
+	parts := strings.SplitN(s, "/", 2)
+	num = SafeInt(parts[0])
+	if len(parts) == 2 {
+		total = SafeInt(parts[1])
+	}
+	return num, total
+}
+
+var scanLibraryExtensions = map[string]bool{
+	".mp3": true,
+}
+
+func runScanLibrary() {
+	if len(os.Args) < 3 {
+		fmt.Fprintln(os.Stderr, "Usage: mp3-scripts scan-library <music-root>")
+		os.Exit(1)
+	}
+	root := os.Args[2]
+	outDir := filepath.Join("..", "data")
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	var reader TagReader = id3v2TagReader{}
+
+	var tracksList []TrackEntry
+	artistsMap := make(map[string]map[string]bool)
+	albumsMap := make(map[string]*albumAgg)
+	var skipped int
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !scanLibraryExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		meta, err := reader.Read(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+			skipped++
+			return nil
+		}
+
+		artist := SanitizeArtistName(meta.Artist)
+		album := SanitizeAlbumName(meta.Album)
+		albumArtist := SanitizeArtistName(meta.AlbumArtist)
+		if !IsValidName(artist) || !IsValidName(album) {
+			skipped++
+			return nil
+		}
+
+		tracksList = append(tracksList, TrackEntry{
+			Track:  strings.TrimSpace(meta.Title),
+			Artist: artist,
+			Album:  album,
+		})
+
+		if _, ok := artistsMap[artist]; !ok {
+			artistsMap[artist] = make(map[string]bool)
+		}
+		artistsMap[artist][album] = true
+
+		agg, ok := albumsMap[album]
+		if !ok {
+			agg = &albumAgg{artists: make(map[string]bool), albumArtists: make(map[string]bool)}
+			albumsMap[album] = agg
+		}
+		agg.artists[artist] = true
+		if albumArtist != "" {
+			agg.albumArtists[albumArtist] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error walking %s: %v\n", root, err)
+		os.Exit(1)
+	}
+
+	writeJSON(filepath.Join(outDir, "tracks.json"), TracksOutput{
+		TotalTracks: len(tracksList),
+		Tracks:      tracksList,
+	})
+
+	var artistList []ArtistEntry
+	for artist, albumSet := range artistsMap {
+		var albums []string
+		for alb := range albumSet {
+			albums = append(albums, alb)
+		}
+		sort.Strings(albums)
+		artistList = append(artistList, ArtistEntry{
+			Artist:    artist,
+			SortName:  ToSortName(artist),
+			OrderName: ToOrderName(artist),
+			Albums:    albums,
+		})
+	}
+	sort.Slice(artistList, func(i, j int) bool { return artistList[i].OrderName < artistList[j].OrderName })
+	writeJSON(filepath.Join(outDir, "artists.json"), ArtistsOutput{
+		TotalArtists: len(artistList),
+		Artists:      artistList,
+	})
+
+	var albumList []AlbumEntry
+	for album, agg := range albumsMap {
+		var artists []string
+		for art := range agg.artists {
+			artists = append(artists, art)
+		}
+		sort.Strings(artists)
+		albumList = append(albumList, AlbumEntry{
+			Album:         album,
+			SortName:      ToSortName(album),
+			OrderName:     ToOrderName(album),
+			AlbumArtist:   agg.resolveAlbumArtist(),
+			IsCompilation: agg.isCompilation(),
+			Artists:       artists,
+		})
+	}
+	sort.Slice(albumList, func(i, j int) bool { return albumList[i].OrderName < albumList[j].OrderName })
+	writeJSON(filepath.Join(outDir, "albums.json"), AlbumsOutput{
+		TotalAlbums: len(albumList),
+		Albums:      albumList,
+	})
+
+	fmt.Printf("Scanned %s\n", root)
+	fmt.Printf("Wrote %d tracks, %d artists, %d albums to %s\n", len(tracksList), len(artistList), len(albumList), outDir)
+	if skipped > 0 {
+		fmt.Printf("Skipped %d files (unreadable tags or missing artist/album)\n", skipped)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// searchIndex is a simple inverted index over the normalized OrderName
+// tokens of every artist and album, so /api/search is O(query tokens)
+// instead of a linear scan over the whole collection.
+type searchIndex struct {
+	artists      []ArtistEntry
+	albums       []AlbumEntry
+	tracks       []TrackEntry
+	artistBySlug map[string]ArtistEntry
+	albumBySlug  map[string]AlbumEntry
+	tokens       map[string]map[string]bool // token -> set of "artist:<slug>" / "album:<slug>"
+}
+
+func buildSearchIndex(artists []ArtistEntry, albums []AlbumEntry, tracks []TrackEntry) *searchIndex {
+	idx := &searchIndex{
+		artists:      artists,
+		albums:       albums,
+		tracks:       tracks,
+		artistBySlug: make(map[string]ArtistEntry),
+		albumBySlug:  make(map[string]AlbumEntry),
+		tokens:       make(map[string]map[string]bool),
+	}
+
+	addTokens := func(id, orderName string) {
+		for _, token := range strings.Fields(orderName) {
+			if idx.tokens[token] == nil {
+				idx.tokens[token] = make(map[string]bool)
+			}
+			idx.tokens[token][id] = true
+		}
+	}
+
+	for _, a := range artists {
+		slug := Slugify(a.Artist)
+		idx.artistBySlug[slug] = a
+		addTokens("artist:"+slug, a.OrderName)
+	}
+	for _, al := range albums {
+		slug := Slugify(al.Album)
+		idx.albumBySlug[slug] = al
+		addTokens("album:"+slug, al.OrderName)
+	}
+
+	return idx
+}
+
+// search returns matching artist/album IDs for a query: every query token
+// must appear somewhere in the entry's OrderName tokens.
+func (idx *searchIndex) search(query string) (artistSlugs, albumSlugs []string) {
+	queryTokens := strings.Fields(ToOrderName(query))
+	if len(queryTokens) == 0 {
+		return nil, nil
+	}
+
+	matches := make(map[string]int)
+	for _, token := range queryTokens {
+		for id := range idx.tokens[token] {
+			matches[id]++
+		}
+	}
+
+	for id, count := range matches {
+		if count != len(queryTokens) {
+			continue
+		}
+		if strings.HasPrefix(id, "artist:") {
+			artistSlugs = append(artistSlugs, strings.TrimPrefix(id, "artist:"))
+		} else if strings.HasPrefix(id, "album:") {
+			albumSlugs = append(albumSlugs, strings.TrimPrefix(id, "album:"))
+		}
+	}
+	return artistSlugs, albumSlugs
+}
+
+func runServe() {
+	addr := ":8080"
+	if len(os.Args) > 2 {
+		addr = os.Args[2]
+	}
+
+	dataDir := filepath.Join("..", "data")
+
+	var tracksOut TracksOutput
+	if err := readJSONFile(filepath.Join(dataDir, "tracks.json"), &tracksOut); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading tracks.json: %v\n", err)
+		os.Exit(1)
+	}
+	var artistsOut ArtistsOutput
+	if err := readJSONFile(filepath.Join(dataDir, "artists.json"), &artistsOut); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading artists.json: %v\n", err)
+		os.Exit(1)
+	}
+	var albumsOut AlbumsOutput
+	if err := readJSONFile(filepath.Join(dataDir, "albums.json"), &albumsOut); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading albums.json: %v\n", err)
+		os.Exit(1)
+	}
+
+	idx := buildSearchIndex(artistsOut.Artists, albumsOut.Albums, tracksOut.Tracks)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/artists", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, artistsOut)
+	})
+	mux.HandleFunc("/api/artists/", func(w http.ResponseWriter, r *http.Request) {
+		slug := strings.TrimPrefix(r.URL.Path, "/api/artists/")
+		artist, ok := idx.artistBySlug[slug]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSONResponse(w, artist)
+	})
+	mux.HandleFunc("/api/albums", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, albumsOut)
+	})
+	mux.HandleFunc("/api/albums/", func(w http.ResponseWriter, r *http.Request) {
+		slug := strings.TrimPrefix(r.URL.Path, "/api/albums/")
+		album, ok := idx.albumBySlug[slug]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSONResponse(w, album)
+	})
+	mux.HandleFunc("/api/tracks", func(w http.ResponseWriter, r *http.Request) {
+		artist := r.URL.Query().Get("artist")
+		album := r.URL.Query().Get("album")
+
+		var filtered []TrackEntry
+		for _, t := range idx.tracks {
+			if artist != "" && t.Artist != artist {
+				continue
+			}
+			if album != "" && t.Album != album {
+				continue
+			}
+			filtered = append(filtered, t)
+		}
+		writeJSONResponse(w, TracksOutput{TotalTracks: len(filtered), Tracks: filtered})
+	})
+	mux.HandleFunc("/api/search", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		artistSlugs, albumSlugs := idx.search(query)
+
+		var artists []ArtistEntry
+		for _, slug := range artistSlugs {
+			artists = append(artists, idx.artistBySlug[slug])
+		}
+		var albums []AlbumEntry
+		for _, slug := range albumSlugs {
+			albums = append(albums, idx.albumBySlug[slug])
+		}
+
+		writeJSONResponse(w, struct {
+			Query   string        `json:"query"`
+			Artists []ArtistEntry `json:"artists"`
+			Albums  []AlbumEntry  `json:"albums"`
+		}{Query: query, Artists: artists, Albums: albums})
+	})
+
+	fmt.Printf("Serving collection API on %s\n", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting server: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func readJSONFile(path string, dest interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(dest)
+}
+
+func writeJSONResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// subsonicSearchIndex is an inverted index over the OrderName tokens of the
+// web-data artist/album indexes - the same artifacts build-web-data used to
+// generate the static docs under web-data/subsonic/, so search3's IDs always
+// resolve to a file that's actually there.
+type subsonicSearchIndex struct {
+	artistBySlug map[string]ArtistIndexEntry
+	albumBySlug  map[string]AlbumIndexEntry
+	tokens       map[string]map[string]bool // token -> set of "artist:<slug>" / "album:<slug>"
+}
+
+func buildSubsonicSearchIndex(artists []ArtistIndexEntry, albums []AlbumIndexEntry) *subsonicSearchIndex {
+	idx := &subsonicSearchIndex{
+		artistBySlug: make(map[string]ArtistIndexEntry),
+		albumBySlug:  make(map[string]AlbumIndexEntry),
+		tokens:       make(map[string]map[string]bool),
+	}
+
+	addTokens := func(id, orderName string) {
+		for _, token := range strings.Fields(orderName) {
+			if idx.tokens[token] == nil {
+				idx.tokens[token] = make(map[string]bool)
+			}
+			idx.tokens[token][id] = true
+		}
+	}
+
+	for _, a := range artists {
+		idx.artistBySlug[a.Slug] = a
+		addTokens("artist:"+a.Slug, a.OrderName)
+	}
+	for _, al := range albums {
+		idx.albumBySlug[al.Slug] = al
+		addTokens("album:"+al.Slug, al.OrderName)
+	}
+
+	return idx
+}
+
+// search returns matching artist/album slugs for a query: every query token
+// must appear somewhere in the entry's OrderName tokens.
+func (idx *subsonicSearchIndex) search(query string) (artistSlugs, albumSlugs []string) {
+	queryTokens := strings.Fields(ToOrderName(query))
+	if len(queryTokens) == 0 {
+		return nil, nil
+	}
+
+	matches := make(map[string]int)
+	for _, token := range queryTokens {
+		for id := range idx.tokens[token] {
+			matches[id]++
+		}
+	}
+
+	for id, count := range matches {
+		if count != len(queryTokens) {
+			continue
+		}
+		if strings.HasPrefix(id, "artist:") {
+			artistSlugs = append(artistSlugs, strings.TrimPrefix(id, "artist:"))
+		} else if strings.HasPrefix(id, "album:") {
+			albumSlugs = append(albumSlugs, strings.TrimPrefix(id, "album:"))
+		}
+	}
+	return artistSlugs, albumSlugs
+}
+
+// runServeSubsonic serves the static documents written by build-web-data
+// --subsonic, wrapping search3 (the one query-dependent endpoint) around an
+// in-memory search index built from those same web-data artifacts.
+func runServeSubsonic() {
+	addr := ":8080"
+	if len(os.Args) > 2 {
+		addr = os.Args[2]
+	}
+
+	webDataDir := filepath.Join("..", "web-data")
+	subsonicDir := filepath.Join(webDataDir, "subsonic")
+
+	var artistIndex ArtistIndex
+	if err := readJSONFile(filepath.Join(webDataDir, "artists-index.json"), &artistIndex); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading artists-index.json: %v\n", err)
+		os.Exit(1)
+	}
+	var albumIndex AlbumIndex
+	if err := readJSONFile(filepath.Join(webDataDir, "albums-index.json"), &albumIndex); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading albums-index.json: %v\n", err)
+		os.Exit(1)
+	}
+
+	idx := buildSubsonicSearchIndex(artistIndex.Artists, albumIndex.Albums)
+
+	mux := http.NewServeMux()
+
+	serveStatic := func(subpath string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, filepath.Join(subsonicDir, subpath))
+		}
+	}
+	mux.HandleFunc("/rest/getArtists", serveStatic("getArtists.json"))
+	mux.HandleFunc("/rest/getAlbumList2", serveStatic("getAlbumList2.json"))
+	mux.HandleFunc("/rest/getArtist", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		http.ServeFile(w, r, filepath.Join(subsonicDir, "artist", id+".json"))
+	})
+	mux.HandleFunc("/rest/getAlbum", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		http.ServeFile(w, r, filepath.Join(subsonicDir, "album", id+".json"))
+	})
+	mux.HandleFunc("/rest/getSong", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		http.ServeFile(w, r, filepath.Join(subsonicDir, "song", id+".json"))
+	})
+	mux.HandleFunc("/rest/search3", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		artistSlugs, albumSlugs := idx.search(strings.TrimSpace(query))
+
+		var artists []subsonicID3Artist
+		for _, slug := range artistSlugs {
+			a := idx.artistBySlug[slug]
+			artists = append(artists, subsonicID3Artist{ID: "artist-" + slug, Name: a.Name, AlbumCount: int32(a.AlbumCount)})
+		}
+		var albums []subsonicID3Album
+		for _, slug := range albumSlugs {
+			al := idx.albumBySlug[slug]
+			albums = append(albums, subsonicID3Album{ID: "album-" + slug, Name: al.Name, SongCount: int32(al.TrackCount)})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(subsonicEnvelope("searchResult3", map[string]interface{}{
+			"artist": artists,
+			"album":  albums,
+		}))
+	})
+
+	fmt.Printf("Serving Subsonic-compatible API on %s (static docs from %s)\n", addr, subsonicDir)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting server: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WebDataSource yields the same normalized CSV-shaped rows the
+// build-web-data pipeline has always consumed, regardless of where the
+// data actually comes from.
+type WebDataSource interface {
+	Rows() ([]map[string]string, error)
+}
+
+// itunesCSVSource is the original, default backend: the iTunes export CSV.
+type itunesCSVSource struct {
+	path string
+}
+
+func (s itunesCSVSource) Rows() ([]map[string]string, error) {
+	return ReadCSV(s.path)
+}
+
+// fsSource walks a music root and reads ID3 tags directly from the files,
+// mapping them onto the same column names the CSV backend uses.
+type fsSource struct {
+	root string
+}
+
+func (s fsSource) Rows() ([]map[string]string, error) {
+	var reader TagReader = id3v2TagReader{}
+	var rows []map[string]string
+
+	err := filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !scanLibraryExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		meta, err := reader.Read(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+			return nil
+		}
+
+		rows = append(rows, map[string]string{
+			"Name":             meta.Title,
+			"Artist":           meta.Artist,
+			"Album":            meta.Album,
+			"Album Artist":     meta.AlbumArtist,
+			"Composer":         meta.Composer,
+			"Genre":            meta.Genre,
+			"Year":             strconv.Itoa(meta.Year),
+			"Track Number":     strconv.Itoa(meta.TrackNumber),
+			"Track Count":      strconv.Itoa(meta.TrackCount),
+			"Disc Number":      strconv.Itoa(meta.DiscNumber),
+			"Disc Count":       strconv.Itoa(meta.DiscCount),
+			"Location":         path,
+		})
+		return nil
+	})
+	return rows, err
+}
+
+// subsonicSource pulls the library from a Subsonic/Navidrome server's REST
+// API instead of a local file or CSV.
+type subsonicSource struct {
+	baseURL  string
+	username string
+	password string
+}
+
+func newSubsonicSource(baseURL, username, password string) subsonicSource {
+	return subsonicSource{baseURL: strings.TrimRight(baseURL, "/"), username: username, password: password}
+}
+
+// authParams builds the token-based auth query params Subsonic expects:
+// an md5(password+salt) token alongside the salt, so the plain password
+// never goes over the wire.
+func (s subsonicSource) authParams() url.Values {
+	salt := fmt.Sprintf("%x", rand.Int63())
+	sum := md5.Sum([]byte(s.password + salt))
+	return url.Values{
+		"u": {s.username},
+		"t": {hex.EncodeToString(sum[:])},
+		"s": {salt},
+		"v": {"1.16.1"},
+		"c": {"mp3-collection-scripts"},
+		"f": {"json"},
+	}
+}
+
+type subsonicSong struct {
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	Album       string `json:"album"`
+	Genre       string `json:"genre"`
+	Year        int    `json:"year"`
+	Track       int    `json:"track"`
+	DiscNumber  int    `json:"discNumber"`
+	Path        string `json:"path"`
+}
+
+func (s subsonicSource) Rows() ([]map[string]string, error) {
+	reqURL := s.baseURL + "/rest/search3?" + s.authParams().Encode() + "&query=%20&songCount=10000"
+
+	resp, err := http.Get(reqURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var parsed struct {
+		SubsonicResponse struct {
+			SearchResult3 struct {
+				Song []subsonicSong `json:"song"`
+			} `json:"searchResult3"`
+		} `json:"subsonic-response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]string
+	for _, song := range parsed.SubsonicResponse.SearchResult3.Song {
+		rows = append(rows, map[string]string{
+			"Name":        song.Title,
+			"Artist":      song.Artist,
+			"Album":       song.Album,
+			"Genre":       song.Genre,
+			"Year":        strconv.Itoa(song.Year),
+			"Track Number": strconv.Itoa(song.Track),
+			"Disc Number": strconv.Itoa(song.DiscNumber),
+			"Location":    song.Path,
+		})
+	}
+	return rows, nil
+}
+
+// sourceFlag reads --source=itunes-csv|fs|subsonic plus its backend-
+// specific config (--music-root, --subsonic-url, --subsonic-user,
+// --subsonic-pass) from the command's arguments and builds the matching
+// WebDataSource. Defaults to the original itunes-csv backend.
+func sourceFlag(defaultCSVPath string) WebDataSource {
+	source := "itunes-csv"
+	musicRoot := ""
+	subsonicURL := ""
+	subsonicUser := ""
+	subsonicPass := ""
+
+	for _, arg := range os.Args[2:] {
+		switch {
+		case strings.HasPrefix(arg, "--source="):
+			source = strings.TrimPrefix(arg, "--source=")
+		case strings.HasPrefix(arg, "--music-root="):
+			musicRoot = strings.TrimPrefix(arg, "--music-root=")
+		case strings.HasPrefix(arg, "--subsonic-url="):
+			subsonicURL = strings.TrimPrefix(arg, "--subsonic-url=")
+		case strings.HasPrefix(arg, "--subsonic-user="):
+			subsonicUser = strings.TrimPrefix(arg, "--subsonic-user=")
+		case strings.HasPrefix(arg, "--subsonic-pass="):
+			subsonicPass = strings.TrimPrefix(arg, "--subsonic-pass=")
+		}
+	}
+
+	switch source {
+	case "fs":
+		return fsSource{root: musicRoot}
+	case "subsonic":
+		return newSubsonicSource(subsonicURL, subsonicUser, subsonicPass)
+	default:
+		return itunesCSVSource{path: defaultCSVPath}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const subsonicAPIVersion = "1.16.1"
+
+// subsonicID3Artist is the Subsonic "ArtistID3" shape.
+type subsonicID3Artist struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	AlbumCount int32  `json:"albumCount"`
+}
+
+// subsonicID3Album is the Subsonic "AlbumID3" shape.
+type subsonicID3Album struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Artist    string `json:"artist"`
+	ArtistID  string `json:"artistId"`
+	SongCount int32  `json:"songCount"`
+	Year      int32  `json:"year,omitempty"`
+	CoverArt  string `json:"coverArt,omitempty"`
+}
+
+// subsonicChild is the Subsonic "Child" shape used for songs.
+type subsonicChild struct {
+	ID       string `json:"id"`
+	Parent   string `json:"parent,omitempty"`
+	IsDir    bool   `json:"isDir"`
+	Title    string `json:"title"`
+	Album    string `json:"album,omitempty"`
+	Artist   string `json:"artist,omitempty"`
+	Track    int32  `json:"track,omitempty"`
+	Year     int32  `json:"year,omitempty"`
+	Genre    string `json:"genre,omitempty"`
+	Duration int32  `json:"duration,omitempty"`
+	BitRate  int32  `json:"bitRate,omitempty"`
+	Path     string `json:"path,omitempty"`
+}
+
+func subsonicSongFromTrack(t WebTrack) subsonicChild {
+	year := int32(0)
+	if t.Year != nil {
+		year = int32(*t.Year)
+	}
+	trackNumber := int32(0)
+	if t.TrackNumber != nil {
+		trackNumber = int32(*t.TrackNumber)
+	}
+	bitRate := int32(0)
+	if t.BitRate != nil {
+		bitRate = int32(*t.BitRate)
+	}
+	return subsonicChild{
+		ID:       t.ID,
+		Parent:   "album-" + t.AlbumSlug,
+		Title:    t.Name,
+		Album:    t.Album,
+		Artist:   t.Artist,
+		Track:    trackNumber,
+		Year:     year,
+		Genre:    t.Genre,
+		Duration: int32(t.Duration),
+		BitRate:  bitRate,
+		Path:     t.Location,
+	}
+}
+
+// subsonicEnvelope wraps a payload in the "subsonic-response" envelope
+// every endpoint response (static or live) must have.
+func subsonicEnvelope(payloadKey string, payload interface{}) map[string]interface{} {
+	body := map[string]interface{}{
+		"status":        "ok",
+		"version":       subsonicAPIVersion,
+		"type":          "mp3-collection-scripts",
+		"serverVersion": "1.0.0",
+	}
+	if payloadKey != "" {
+		body[payloadKey] = payload
+	}
+	return map[string]interface{}{"subsonic-response": body}
+}
+
+// buildSubsonicData writes static JSON documents under outputDir/subsonic/
+// that mirror the shape of the real Subsonic API responses, so any
+// Subsonic client can browse a pre-built copy of the collection without a
+// running server.
+func buildSubsonicData(outputDir string, artistIndexList []ArtistIndexEntry, albumIndexList []AlbumIndexEntry, tracks []WebTrack) {
+	subsonicDir := filepath.Join(outputDir, "subsonic")
+	artistDir := filepath.Join(subsonicDir, "artist")
+	albumDir := filepath.Join(subsonicDir, "album")
+	songDir := filepath.Join(subsonicDir, "song")
+	for _, dir := range []string{artistDir, albumDir, songDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", dir, err)
+			os.Exit(1)
+		}
+	}
+
+	albumBySlug := make(map[string]AlbumIndexEntry, len(albumIndexList))
+	for _, a := range albumIndexList {
+		albumBySlug[a.Slug] = a
+	}
+
+	tracksByAlbum := make(map[string][]WebTrack)
+	for _, t := range tracks {
+		tracksByAlbum[t.AlbumSlug] = append(tracksByAlbum[t.AlbumSlug], t)
+		writeJSON(filepath.Join(songDir, t.ID+".json"), subsonicEnvelope("song", subsonicSongFromTrack(t)))
+	}
+
+	// getArtists: group artists under their first OrderName letter, the
+	// way Subsonic's own index does.
+	indexGroups := make(map[string][]subsonicID3Artist)
+	for _, a := range artistIndexList {
+		id := "artist-" + a.Slug
+		letter := "#"
+		if a.OrderName != "" {
+			letter = strings.ToUpper(a.OrderName[:1])
+		}
+		indexGroups[letter] = append(indexGroups[letter], subsonicID3Artist{
+			ID:         id,
+			Name:       a.Name,
+			AlbumCount: int32(a.AlbumCount),
+		})
+
+		// getArtist/{id}: this artist plus its albums.
+		var albums []subsonicID3Album
+		for _, albumName := range a.Albums {
+			slug := Slugify(albumName)
+			album, ok := albumBySlug[slug]
+			if !ok {
+				continue
+			}
+			albums = append(albums, subsonicID3Album{
+				ID:        "album-" + album.Slug,
+				Name:      album.Name,
+				Artist:    a.Name,
+				ArtistID:  id,
+				SongCount: int32(album.TrackCount),
+				CoverArt:  album.CoverArtURL,
+			})
+		}
+		writeJSON(filepath.Join(artistDir, id+".json"), subsonicEnvelope("artist", map[string]interface{}{
+			"id":         id,
+			"name":       a.Name,
+			"albumCount": int32(a.AlbumCount),
+			"album":      albums,
+		}))
+	}
+
+	var letters []string
+	for letter := range indexGroups {
+		letters = append(letters, letter)
+	}
+	sort.Strings(letters)
+
+	var index []map[string]interface{}
+	for _, letter := range letters {
+		index = append(index, map[string]interface{}{
+			"name":   letter,
+			"artist": indexGroups[letter],
+		})
+	}
+	writeJSON(filepath.Join(subsonicDir, "getArtists.json"), subsonicEnvelope("artists", map[string]interface{}{
+		"ignoredArticles": strings.Join(leadingArticles, " "),
+		"index":           index,
+	}))
+
+	// getAlbum/{id} and getAlbumList2
+	var albumList []subsonicID3Album
+	for _, album := range albumIndexList {
+		id := "album-" + album.Slug
+		artistID := ""
+		artistName := ""
+		if len(album.Artists) > 0 {
+			artistName = album.Artists[0]
+			artistID = "artist-" + Slugify(artistName)
+		}
+
+		summary := subsonicID3Album{
+			ID:        id,
+			Name:      album.Name,
+			Artist:    artistName,
+			ArtistID:  artistID,
+			SongCount: int32(album.TrackCount),
+			CoverArt:  album.CoverArtURL,
+		}
+		albumList = append(albumList, summary)
+
+		var songs []subsonicChild
+		for _, t := range tracksByAlbum[album.Slug] {
+			songs = append(songs, subsonicSongFromTrack(t))
+		}
+		writeJSON(filepath.Join(albumDir, id+".json"), subsonicEnvelope("album", map[string]interface{}{
+			"id":        summary.ID,
+			"name":      summary.Name,
+			"artist":    summary.Artist,
+			"artistId":  summary.ArtistID,
+			"songCount": summary.SongCount,
+			"coverArt":  summary.CoverArt,
+			"song":      songs,
+		}))
+	}
+	writeJSON(filepath.Join(subsonicDir, "getAlbumList2.json"), subsonicEnvelope("albumList2", map[string]interface{}{
+		"album": albumList,
+	}))
+
+	fmt.Printf("Wrote Subsonic-compatible API documents under %s\n", subsonicDir)
+}
+
+// subsonicFlag checks for --subsonic among the command's arguments.
+func subsonicFlag() bool {
+	for _, arg := range os.Args[2:] {
+		if arg == "--subsonic" {
+			return true
+		}
+	}
+	return false
+}